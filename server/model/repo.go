@@ -0,0 +1,50 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+// Repo represents a repository being built.
+type Repo struct {
+	ID       int64  `json:"id,omitempty" xorm:"pk autoincr 'id'"`
+	FullName string `json:"full_name" xorm:"UNIQUE INDEX 'full_name'"`
+
+	// Config is the user-defined pipeline config path, file, folder or
+	// doublestar glob pattern. Empty means follow the default lookup order.
+	Config string `json:"config_file" xorm:"varchar(500) 'config_path'"`
+
+	// ConfigPathDepth bounds how many directory levels forge.Dir recurses
+	// when Config names a folder. A "**" glob in Config is recursive by
+	// definition, so it's automatically given at least globRecursiveDepth
+	// (20) levels regardless of this setting - see globPatternDepth.
+	ConfigPathDepth int `json:"config_path_depth" xorm:"NOT NULL DEFAULT 1 'config_path_depth'"`
+
+	// IgnoreTemplateFiles is a deprecated shortcut for excluding any config
+	// file with "template" in its name, matched case-insensitively. Kept
+	// for backwards compatibility - see ConfigExcludePatterns for the
+	// general mechanism.
+	IgnoreTemplateFiles bool `json:"ignore_template_files" xorm:"NOT NULL DEFAULT false 'ignore_template_files'"`
+
+	// ConfigExcludePatterns are dockerignore-style patterns applied on top
+	// of .woodpeckerignore to drop config files from pipeline discovery.
+	ConfigExcludePatterns []string `json:"config_exclude_patterns" xorm:"json 'config_exclude_patterns'"`
+
+	// SecretScanPolicy controls the pre-execution secret leak scan of
+	// fetched pipeline configs: "off" (default), "warn" or "block".
+	SecretScanPolicy string `json:"secret_scan_policy" xorm:"varchar(10) NOT NULL DEFAULT 'off' 'secret_scan_policy'"`
+
+	// SecretGroupPatterns are the secret prefix-group patterns (e.g.
+	// "PROD_{??}") used both to group secrets by environment and by the
+	// leak scanner's "${secret.NAME}" suggestion.
+	SecretGroupPatterns []string `json:"secret_group_patterns" xorm:"json 'secret_group_patterns'"`
+}
@@ -21,24 +21,75 @@ import (
 	"strings"
 	"time"
 
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/moby/patternmatcher"
 	"github.com/rs/zerolog/log"
 
 	"go.woodpecker-ci.org/woodpecker/v3/server/forge"
 	"go.woodpecker-ci.org/woodpecker/v3/server/forge/types"
 	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+	"go.woodpecker-ci.org/woodpecker/v3/server/services/config/scan"
 	"go.woodpecker-ci.org/woodpecker/v3/shared/constant"
 )
 
+// woodpeckerIgnoreFile is a dockerignore-style file at the repo root that
+// excludes config files from being picked up as pipelines, even though they
+// live inside the configured config folder (e.g. templates or drafts).
+const woodpeckerIgnoreFile = ".woodpeckerignore"
+
+// templateCompatNeedle is checked case-insensitively against a file's full
+// path when repo.IgnoreTemplateFiles is set, reproducing the old boolean's
+// exact behavior on top of the new, more general mechanism. moby/patternmatcher
+// matches case-sensitively, so this check is applied separately rather than
+// folded into an ignore pattern.
+const templateCompatNeedle = "template"
+
+// SecretLister supplies the secrets known to a repo, so the leak scanner can
+// recognize when a config should be referencing "${secret.NAME}" instead of
+// a literal value.
+type SecretLister interface {
+	SecretListAll(repo *model.Repo) ([]*model.Secret, error)
+}
+
 type forgeFetcher struct {
-	timeout    time.Duration
-	retryCount uint
+	timeout       time.Duration
+	retryCount    uint
+	secretLister  SecretLister
+	adminRuleDefs []constant.SecretScanRuleDef
+}
+
+// Option configures optional behavior of a forgeFetcher built by NewForge.
+type Option func(*forgeFetcher)
+
+// WithSecretScan enables the pre-execution secret leak scan, using lister to
+// resolve the repo's known secrets for the "should reference a secret
+// instead" cross-check.
+func WithSecretScan(lister SecretLister) Option {
+	return func(f *forgeFetcher) {
+		f.secretLister = lister
+	}
+}
+
+// WithSecretScanRules extends the built-in secret scan ruleset with rules
+// loaded from admin config, so operators can add detection rules without
+// recompiling.
+func WithSecretScanRules(defs []constant.SecretScanRuleDef) Option {
+	return func(f *forgeFetcher) {
+		f.adminRuleDefs = defs
+	}
 }
 
-func NewForge(timeout time.Duration, retries uint) Service {
-	return &forgeFetcher{
+func NewForge(timeout time.Duration, retries uint, opts ...Option) Service {
+	f := &forgeFetcher{
 		timeout:    timeout,
 		retryCount: retries,
 	}
+
+	for _, opt := range opts {
+		opt(f)
+	}
+
+	return f
 }
 
 func (f *forgeFetcher) Fetch(ctx context.Context, forge forge.Forge, user *model.User, repo *model.Repo, pipeline *model.Pipeline, oldConfigData []*types.FileMeta, restart bool) (files []*types.FileMeta, err error) {
@@ -65,7 +116,78 @@ func (f *forgeFetcher) Fetch(ctx context.Context, forge forge.Forge, user *model
 		}
 	}
 
-	return files, err
+	if err != nil {
+		return files, err
+	}
+
+	if scanErr := f.scanConfigs(ctx, repo, files); scanErr != nil {
+		return nil, scanErr
+	}
+
+	return files, nil
+}
+
+// scanConfigs runs the pre-execution secret leak scan over the fetched
+// config files and, depending on repo.SecretScanPolicy, either logs a
+// warning or returns a *scan.ErrSecretsFound that stops pipeline creation.
+func (f *forgeFetcher) scanConfigs(_ context.Context, repo *model.Repo, files []*types.FileMeta) error {
+	policy := scan.Policy(repo.SecretScanPolicy)
+	if policy == "" || policy == scan.PolicyOff {
+		return nil
+	}
+
+	var knownSecrets []*model.Secret
+	if f.secretLister != nil {
+		var err error
+		knownSecrets, err = f.secretLister.SecretListAll(repo)
+		if err != nil {
+			log.Error().Err(err).Str("repo", repo.FullName).Msg("secret scan: could not list known secrets")
+		}
+	}
+
+	scanner := scan.NewScanner(f.secretScanRules(repo))
+
+	var results []scan.ConfigScanResult
+	for _, file := range files {
+		results = append(results, scanner.Scan(file, knownSecrets, repo.SecretGroupPatterns)...)
+	}
+
+	if len(results) == 0 {
+		return nil
+	}
+
+	for _, res := range results {
+		log.Warn().Str("repo", repo.FullName).Str("file", res.File).Int("line", res.Line).
+			Str("rule", res.RuleID).Str("severity", string(res.Severity)).Str("suggestedSecret", res.SuggestedSecret).
+			Msgf("potential secret leak in pipeline config: %s", res.Preview)
+	}
+
+	if policy == scan.PolicyBlock {
+		return &scan.ErrSecretsFound{Results: results}
+	}
+
+	return nil
+}
+
+// secretScanRules compiles the built-in ruleset extended with any
+// admin-supplied rule table. A malformed admin rule falls back to the
+// built-in ruleset rather than failing the fetch.
+func (f *forgeFetcher) secretScanRules(repo *model.Repo) []scan.Rule {
+	if len(f.adminRuleDefs) == 0 {
+		return scan.BuiltinRules()
+	}
+
+	defs := make([]constant.SecretScanRuleDef, 0, len(constant.DefaultSecretScanRules)+len(f.adminRuleDefs))
+	defs = append(defs, constant.DefaultSecretScanRules...)
+	defs = append(defs, f.adminRuleDefs...)
+
+	rules, err := scan.RulesFromDefs(defs)
+	if err != nil {
+		log.Error().Err(err).Str("repo", repo.FullName).Msg("secret scan: invalid admin rule table, falling back to built-in rules")
+		return scan.BuiltinRules()
+	}
+
+	return rules
 }
 
 type forgeFetcherContext struct {
@@ -74,6 +196,10 @@ type forgeFetcherContext struct {
 	repo     *model.Repo
 	pipeline *model.Pipeline
 	timeout  time.Duration
+
+	// ignoreMatcher is populated once per fetch from .woodpeckerignore and
+	// repo.ConfigExcludePatterns, and consulted by filterPipelineFiles.
+	ignoreMatcher *patternmatcher.PatternMatcher
 }
 
 // fetch attempts to fetch the configuration file(s) for the given config string.
@@ -81,6 +207,10 @@ func (f *forgeFetcherContext) fetch(c context.Context, config string) ([]*types.
 	ctx, cancel := context.WithTimeout(c, f.timeout)
 	defer cancel()
 
+	if err := f.loadIgnoreMatcher(ctx); err != nil {
+		return nil, fmt.Errorf("configFetcher[%s]: could not parse %s: %w", f.repo.FullName, woodpeckerIgnoreFile, err)
+	}
+
 	if len(config) > 0 {
 		log.Trace().Msgf("configFetcher[%s]: use user config '%s'", f.repo.FullName, config)
 
@@ -110,13 +240,44 @@ func (f *forgeFetcherContext) fetch(c context.Context, config string) ([]*types.
 	}
 }
 
-func (f *forgeFetcherContext) filterPipelineFiles(files []*types.FileMeta) []*types.FileMeta {
+// loadIgnoreMatcher builds the pattern matcher used to drop config files that
+// the repo wants excluded, combining .woodpeckerignore (if present at the
+// repo root) with repo.ConfigExcludePatterns. The separate
+// IgnoreTemplateFiles compat shim is applied directly in isIgnored.
+func (f *forgeFetcherContext) loadIgnoreMatcher(c context.Context) error {
+	var patterns []string
+
+	if data, err := f.forge.File(c, f.user, f.repo, f.pipeline, woodpeckerIgnoreFile); err == nil && len(data) != 0 {
+		for _, line := range strings.Split(string(data), "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			patterns = append(patterns, line)
+		}
+	}
+
+	patterns = append(patterns, f.repo.ConfigExcludePatterns...)
+
+	if len(patterns) == 0 {
+		return nil
+	}
+
+	matcher, err := patternmatcher.New(patterns)
+	if err != nil {
+		return err
+	}
+	f.ignoreMatcher = matcher
+
+	return nil
+}
+
+func (f *forgeFetcherContext) filterPipelineFiles(files []*types.FileMeta, basePath string) []*types.FileMeta {
 	var res []*types.FileMeta
 
 	for _, file := range files {
 		if strings.HasSuffix(file.Name, ".yml") || strings.HasSuffix(file.Name, ".yaml") {
-			// Optionally ignore files with "template" in their name based on repo setting
-			if f.repo.IgnoreTemplateFiles && strings.Contains(strings.ToLower(file.Name), "template") {
+			if f.isIgnored(file.Name, basePath) {
 				continue
 			}
 			res = append(res, file)
@@ -126,6 +287,52 @@ func (f *forgeFetcherContext) filterPipelineFiles(files []*types.FileMeta) []*ty
 	return res
 }
 
+// isIgnored reports whether path, relative to basePath (the config folder
+// being searched), matches the ignore patterns loaded by loadIgnoreMatcher,
+// or the case-insensitive IgnoreTemplateFiles compat check.
+func (f *forgeFetcherContext) isIgnored(path, basePath string) bool {
+	if f.repo.IgnoreTemplateFiles && strings.Contains(strings.ToLower(path), templateCompatNeedle) {
+		return true
+	}
+
+	if f.ignoreMatcher == nil {
+		return false
+	}
+
+	relPath := strings.TrimPrefix(path, basePath)
+	relPath = strings.TrimPrefix(relPath, "/")
+
+	ignored, err := f.ignoreMatcher.MatchesOrParentMatches(relPath)
+	if err != nil {
+		log.Warn().Err(err).Str("repo", f.repo.FullName).Msgf("could not evaluate %s pattern against '%s'", woodpeckerIgnoreFile, path)
+		return false
+	}
+
+	return ignored
+}
+
+// isGlobPattern reports whether config contains any doublestar-style glob
+// metacharacters, e.g. ".woodpecker/**/*.yaml" or "ci/{build,deploy}-*.yml".
+func isGlobPattern(config string) bool {
+	return strings.ContainsAny(config, "*?[{")
+}
+
+// globBaseDir returns the deepest directory that does not contain any glob
+// metacharacters, so it can be passed to forge.Dir as the folder to list.
+func globBaseDir(pattern string) string {
+	idx := strings.IndexAny(pattern, "*?[{")
+	if idx == -1 {
+		return ""
+	}
+
+	prefix := pattern[:idx]
+	if i := strings.LastIndex(prefix, "/"); i != -1 {
+		return prefix[:i]
+	}
+
+	return ""
+}
+
 func validateUniqueFileNames(files []*types.FileMeta) error {
 	seen := make(map[string]string)
 	for _, file := range files {
@@ -143,6 +350,22 @@ func validateUniqueFileNames(files []*types.FileMeta) error {
 	return nil
 }
 
+// validateUniqueFilePaths ensures glob results don't contain the exact same
+// path twice. Unlike validateUniqueFileNames it intentionally allows the
+// same base name at different paths, since that's the whole point of a
+// pattern like ".woodpecker/**/pipeline.yaml": one "pipeline.yaml" per
+// service, nested under different directories.
+func validateUniqueFilePaths(files []*types.FileMeta) error {
+	seen := make(map[string]bool, len(files))
+	for _, file := range files {
+		if seen[file.Name] {
+			return fmt.Errorf("duplicate config file path '%s' found", file.Name)
+		}
+		seen[file.Name] = true
+	}
+	return nil
+}
+
 func (f *forgeFetcherContext) checkPipelineFile(c context.Context, config string) ([]*types.FileMeta, error) {
 	file, err := f.forge.File(c, f.user, f.repo, f.pipeline, config)
 
@@ -158,12 +381,98 @@ func (f *forgeFetcherContext) checkPipelineFile(c context.Context, config string
 	return nil, err
 }
 
+// globRecursiveDepth is the directory depth passed to forge.Dir for a glob
+// pattern containing "**", which by definition matches across an arbitrary
+// number of nested directories - the shallow ConfigPathDepth default (1)
+// would otherwise silently stop forge.Dir from ever returning the nested
+// entries doublestar.Match is trying to match against.
+const globRecursiveDepth = 20
+
+// globPatternDepth returns the directory depth forge.Dir should recurse to
+// in order to satisfy pattern: configuredDepth as-is, unless pattern
+// contains "**" and configuredDepth is too shallow to reach it.
+func globPatternDepth(pattern string, configuredDepth int) int {
+	if strings.Contains(pattern, "**") && configuredDepth < globRecursiveDepth {
+		return globRecursiveDepth
+	}
+	return configuredDepth
+}
+
+// fetchGlobConfig resolves a doublestar glob pattern such as
+// ".woodpecker/**/*.yaml" by listing the deepest common prefix directory via
+// forge.Dir and then filtering the returned entries with a doublestar match.
+func (f *forgeFetcherContext) fetchGlobConfig(c context.Context, pattern string) ([]*types.FileMeta, error) {
+	basePath := globBaseDir(pattern)
+
+	files, err := f.forge.Dir(c, f.user, f.repo, f.pipeline, basePath, globPatternDepth(pattern, f.repo.ConfigPathDepth))
+	if err != nil {
+		return nil, err
+	}
+
+	var matched []*types.FileMeta
+	for _, file := range files {
+		ok, err := doublestar.Match(pattern, file.Name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid glob pattern '%s': %w", pattern, err)
+		}
+		if ok {
+			matched = append(matched, file)
+		}
+	}
+
+	return matched, nil
+}
+
 func (f *forgeFetcherContext) getFirstAvailableConfig(c context.Context, configs []string) ([]*types.FileMeta, error) {
 	var forgeErr []error
 	var debugInfo []string
 
 	for _, fileOrFolder := range configs {
 		log.Trace().Msgf("fetching %s from forge", fileOrFolder)
+		if isGlobPattern(fileOrFolder) {
+			// config is a glob pattern, e.g. ".woodpecker/**/*.yaml"
+			basePath := globBaseDir(fileOrFolder)
+			files, err := f.fetchGlobConfig(c, fileOrFolder)
+			if err != nil {
+				if !errors.Is(err, types.ErrNotImplemented) && !errors.Is(err, &types.ErrConfigNotFound{}) {
+					log.Error().Err(err).Str("repo", f.repo.FullName).Str("user", f.user.Login).Msgf("could not resolve glob pattern from forge: %s", err)
+					forgeErr = append(forgeErr, err)
+					debugInfo = append(debugInfo, fmt.Sprintf("%s: error - %v", fileOrFolder, err))
+				} else {
+					debugInfo = append(debugInfo, fmt.Sprintf("%s: not found or not implemented", fileOrFolder))
+				}
+				continue
+			}
+
+			allFileNames := make([]string, len(files))
+			for i, file := range files {
+				allFileNames[i] = file.Name
+			}
+
+			files = f.filterPipelineFiles(files, basePath)
+			if len(files) != 0 {
+				// Glob results are expected to repeat base names across
+				// directories (e.g. one "pipeline.yaml" per service), so
+				// only guard against the forge literally returning the
+				// same path twice, not against shared base names.
+				if err := validateUniqueFilePaths(files); err != nil {
+					log.Error().Err(err).Str("repo", f.repo.FullName).Msgf("duplicate config file paths found")
+					return nil, err
+				}
+				fileNames := make([]string, len(files))
+				for i, file := range files {
+					fileNames[i] = file.Name
+				}
+				log.Info().Str("repo", f.repo.FullName).Msgf("found %d config files matching pattern '%s': %v", len(files), fileOrFolder, fileNames)
+				return files, nil
+			}
+
+			msg := fmt.Sprintf("%s: matched %d items but none are .yml/.yaml files: %v", fileOrFolder, len(allFileNames), allFileNames)
+			log.Debug().Str("repo", f.repo.FullName).Msg(msg)
+			debugInfo = append(debugInfo, msg)
+			continue
+		}
+
 		if strings.HasSuffix(fileOrFolder, "/") {
 			// config is a folder
 			basePath := strings.TrimSuffix(fileOrFolder, "/")
@@ -188,7 +497,7 @@ func (f *forgeFetcherContext) getFirstAvailableConfig(c context.Context, configs
 				allFileNames[i] = file.Name
 			}
 
-			files = f.filterPipelineFiles(files)
+			files = f.filterPipelineFiles(files, basePath)
 			if len(files) != 0 {
 				// Validate that all file names are unique
 				if err := validateUniqueFileNames(files); err != nil {
@@ -0,0 +1,143 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server/forge/types"
+	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+	"go.woodpecker-ci.org/woodpecker/v3/server/services/config/scan"
+	"go.woodpecker-ci.org/woodpecker/v3/shared/constant"
+)
+
+func TestIsGlobPattern(t *testing.T) {
+	assert.True(t, isGlobPattern(".woodpecker/**/*.yaml"))
+	assert.True(t, isGlobPattern("ci/{build,deploy}-*.yml"))
+	assert.True(t, isGlobPattern("ci/file[0-9].yml"))
+	assert.False(t, isGlobPattern(".woodpecker.yaml"))
+	assert.False(t, isGlobPattern(".woodpecker/"))
+}
+
+func TestGlobBaseDir(t *testing.T) {
+	assert.Equal(t, ".woodpecker", globBaseDir(".woodpecker/**/*.yaml"))
+	assert.Equal(t, "ci", globBaseDir("ci/{build,deploy}-*.yml"))
+	assert.Equal(t, "", globBaseDir("*.yaml"))
+}
+
+func TestGlobPatternDepthBumpsRecursiveGlobs(t *testing.T) {
+	assert.Equal(t, globRecursiveDepth, globPatternDepth(".woodpecker/**/pipeline.yaml", 1))
+	assert.Equal(t, 30, globPatternDepth(".woodpecker/**/pipeline.yaml", 30))
+}
+
+func TestGlobPatternDepthLeavesNonRecursiveGlobsAlone(t *testing.T) {
+	assert.Equal(t, 1, globPatternDepth(".woodpecker/*.yaml", 1))
+}
+
+func TestValidateUniqueFilePathsAllowsSameBaseNameAtDifferentPaths(t *testing.T) {
+	// this is the flagship ".woodpecker/**/pipeline.yaml" use case: every
+	// matched service has its own "pipeline.yaml", which must not be
+	// rejected as a duplicate.
+	files := []*types.FileMeta{
+		{Name: ".woodpecker/service-a/pipeline.yaml"},
+		{Name: ".woodpecker/service-b/pipeline.yaml"},
+	}
+
+	assert.NoError(t, validateUniqueFilePaths(files))
+}
+
+func TestValidateUniqueFilePathsRejectsExactDuplicatePath(t *testing.T) {
+	files := []*types.FileMeta{
+		{Name: ".woodpecker/service-a/pipeline.yaml"},
+		{Name: ".woodpecker/service-a/pipeline.yaml"},
+	}
+
+	assert.Error(t, validateUniqueFilePaths(files))
+}
+
+func TestValidateUniqueFileNamesRejectsSameBaseNameInFlatFolder(t *testing.T) {
+	// the plain folder lookup still dedupes by base name, since
+	// "build.yml" and "build.yaml" in the same flat listing are ambiguous.
+	files := []*types.FileMeta{
+		{Name: ".woodpecker/build.yml"},
+		{Name: ".woodpecker/build.yaml"},
+	}
+
+	assert.Error(t, validateUniqueFileNames(files))
+}
+
+func TestFilterPipelineFilesKeepsOnlyYAMLWithoutIgnoreMatcher(t *testing.T) {
+	f := &forgeFetcherContext{repo: &model.Repo{}}
+
+	files := []*types.FileMeta{
+		{Name: ".woodpecker/build.yaml"},
+		{Name: ".woodpecker/build.yml"},
+		{Name: ".woodpecker/README.md"},
+	}
+
+	res := f.filterPipelineFiles(files, ".woodpecker")
+	assert.Len(t, res, 2)
+}
+
+func TestIsIgnoredTemplateCompatIsCaseInsensitive(t *testing.T) {
+	f := &forgeFetcherContext{repo: &model.Repo{IgnoreTemplateFiles: true}}
+
+	// matches the old `strings.Contains(strings.ToLower(name), "template")`
+	// behavior regardless of casing
+	assert.True(t, f.isIgnored(".woodpecker/Template.yaml", ".woodpecker"))
+	assert.True(t, f.isIgnored(".woodpecker/TEMPLATE.yaml", ".woodpecker"))
+	assert.True(t, f.isIgnored(".woodpecker/MyTemplate.yml", ".woodpecker"))
+	assert.False(t, f.isIgnored(".woodpecker/build.yaml", ".woodpecker"))
+}
+
+func TestIsIgnoredTemplateCompatDisabledByDefault(t *testing.T) {
+	f := &forgeFetcherContext{repo: &model.Repo{}}
+
+	assert.False(t, f.isIgnored(".woodpecker/Template.yaml", ".woodpecker"))
+}
+
+func TestSecretScanRulesFallsBackToBuiltinWithoutAdminRules(t *testing.T) {
+	f := &forgeFetcher{}
+
+	rules := f.secretScanRules(&model.Repo{FullName: "acme/demo"})
+	assert.Equal(t, scan.BuiltinRules(), rules)
+}
+
+func TestSecretScanRulesMergesAdminRules(t *testing.T) {
+	f := &forgeFetcher{adminRuleDefs: []constant.SecretScanRuleDef{
+		{ID: "custom-internal-token", Pattern: `INTERNAL_[A-Z0-9]{16}`, Severity: "high"},
+	}}
+
+	rules := f.secretScanRules(&model.Repo{FullName: "acme/demo"})
+
+	found := false
+	for _, r := range rules {
+		if r.ID() == "custom-internal-token" {
+			found = true
+		}
+	}
+	assert.True(t, found, "admin-supplied rule should be present in the compiled ruleset")
+}
+
+func TestSecretScanRulesFallsBackOnInvalidAdminRule(t *testing.T) {
+	f := &forgeFetcher{adminRuleDefs: []constant.SecretScanRuleDef{
+		{ID: "broken", Pattern: "(unterminated", Severity: "high"},
+	}}
+
+	rules := f.secretScanRules(&model.Repo{FullName: "acme/demo"})
+	assert.Equal(t, scan.BuiltinRules(), rules)
+}
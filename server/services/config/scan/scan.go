@@ -0,0 +1,294 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package scan performs a signature-based scan of fetched pipeline config
+// files, looking for literal secrets (API keys, tokens, PEM blocks, generic
+// high-entropy values) that should instead be referenced via ${secret.NAME}.
+package scan
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"math"
+	"regexp"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server/forge/types"
+	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+	"go.woodpecker-ci.org/woodpecker/v3/server/services/secret"
+	"go.woodpecker-ci.org/woodpecker/v3/shared/constant"
+)
+
+// Severity is the risk level raised by a matching Rule.
+type Severity string
+
+const (
+	SeverityLow      Severity = "low"
+	SeverityMedium   Severity = "medium"
+	SeverityHigh     Severity = "high"
+	SeverityCritical Severity = "critical"
+)
+
+// Policy controls what happens when a scan finds a potential secret leak.
+type Policy string
+
+const (
+	PolicyOff   Policy = "off"
+	PolicyWarn  Policy = "warn"
+	PolicyBlock Policy = "block"
+)
+
+// Rule detects one class of secret leak in a single line of config.
+type Rule interface {
+	// ID uniquely identifies the rule, e.g. "aws-access-key-id".
+	ID() string
+	// Severity is the risk level raised when the rule matches.
+	Severity() Severity
+	// Match reports whether line contains a match for this rule, returning
+	// the matched substring so it can be redacted for the preview.
+	Match(line string) (matched string, ok bool)
+}
+
+// ConfigScanResult is a single finding from scanning a config file.
+type ConfigScanResult struct {
+	File     string
+	Line     int
+	RuleID   string
+	Severity Severity
+	// Preview is a redacted excerpt of the match, safe to log or display.
+	Preview string
+	// SuggestedSecret, if non-empty, is either the name of a secret already
+	// known to the repo whose normalized name equals this line's key, or the
+	// secret group that the key falls into under the repo's prefix patterns.
+	SuggestedSecret string
+}
+
+// ErrSecretsFound is returned when policy is PolicyBlock and the scan found
+// at least one match, so pipeline creation can be short-circuited.
+type ErrSecretsFound struct {
+	Results []ConfigScanResult
+}
+
+func (e *ErrSecretsFound) Error() string {
+	return fmt.Sprintf("pipeline config blocked: %d potential secret leak(s) found", len(e.Results))
+}
+
+// regexRule matches a single compiled regular expression.
+type regexRule struct {
+	id       string
+	severity Severity
+	re       *regexp.Regexp
+}
+
+func (r *regexRule) ID() string         { return r.id }
+func (r *regexRule) Severity() Severity { return r.severity }
+func (r *regexRule) Match(line string) (string, bool) {
+	m := r.re.FindString(line)
+	if m == "" {
+		return "", false
+	}
+	return m, true
+}
+
+// kvLineRe matches a simple "KEY: value" or "KEY=value" config line and
+// captures the key and the (quoted) value separately.
+var kvLineRe = regexp.MustCompile(`^\s*([A-Za-z_][A-Za-z0-9_]*)\s*[:=]\s*['"]?([^'"\s]{16,})['"]?\s*$`)
+
+// entropyRule flags "KEY=VALUE"-shaped lines whose value looks like a
+// high-entropy secret rather than a real word or placeholder.
+type entropyRule struct {
+	id        string
+	severity  Severity
+	threshold float64
+}
+
+func (r *entropyRule) ID() string         { return r.id }
+func (r *entropyRule) Severity() Severity { return r.severity }
+
+func (r *entropyRule) Match(line string) (string, bool) {
+	m := kvLineRe.FindStringSubmatch(line)
+	if m == nil {
+		return "", false
+	}
+
+	value := m[2]
+	if shannonEntropy(value) < r.threshold {
+		return "", false
+	}
+
+	return value, true
+}
+
+// shannonEntropy returns the Shannon entropy of s, in bits per character.
+func shannonEntropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+
+	counts := make(map[rune]int)
+	runes := 0
+	for _, r := range s {
+		counts[r]++
+		runes++
+	}
+
+	n := float64(runes)
+	var entropy float64
+	for _, c := range counts {
+		p := float64(c) / n
+		entropy -= p * math.Log2(p)
+	}
+
+	return entropy
+}
+
+// BuiltinRules returns the default, always-available ruleset. The built-in
+// patterns are fixed at compile time and covered by tests, so a compile
+// failure here is a programming error, not an operator misconfiguration.
+func BuiltinRules() []Rule {
+	rules, err := RulesFromDefs(constant.DefaultSecretScanRules)
+	if err != nil {
+		panic(fmt.Sprintf("scan: invalid built-in rule: %v", err))
+	}
+	return rules
+}
+
+// RulesFromDefs compiles a rule table, e.g. the built-in ruleset extended
+// with rules loaded from admin config, so operators can extend it without
+// recompiling. It returns an error instead of panicking so a single
+// malformed operator-supplied regex doesn't take down the server.
+func RulesFromDefs(defs []constant.SecretScanRuleDef) ([]Rule, error) {
+	rules := make([]Rule, 0, len(defs)+1)
+
+	for _, def := range defs {
+		re, err := regexp.Compile(def.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid secret scan rule %q: %w", def.ID, err)
+		}
+		rules = append(rules, &regexRule{
+			id:       def.ID,
+			severity: Severity(def.Severity),
+			re:       re,
+		})
+	}
+
+	rules = append(rules, &entropyRule{
+		id:        "generic-high-entropy-kv",
+		severity:  SeverityMedium,
+		threshold: 4.0,
+	})
+
+	return rules, nil
+}
+
+// Scanner runs a set of Rules over fetched pipeline config files.
+type Scanner struct {
+	rules []Rule
+}
+
+// NewScanner builds a Scanner from rules, falling back to BuiltinRules when
+// rules is empty.
+func NewScanner(rules []Rule) *Scanner {
+	if len(rules) == 0 {
+		rules = BuiltinRules()
+	}
+	return &Scanner{rules: rules}
+}
+
+// Scan runs every rule line-by-line over file.Data, cross-checking KV-shaped
+// matches against knownSecrets and groupPatterns (the repo's secret group
+// prefix patterns, see server/services/secret) so a literal that should be
+// "${secret.NAME}" is reported with a suggestion instead of silently passed.
+func (s *Scanner) Scan(file *types.FileMeta, knownSecrets []*model.Secret, groupPatterns []string) []ConfigScanResult {
+	var results []ConfigScanResult
+
+	lineNo := 0
+	sc := bufio.NewScanner(bytes.NewReader(file.Data))
+	// config files can legitimately contain very long lines, e.g. an inlined
+	// base64 certificate - grow past bufio's default 64KB token limit so a
+	// long line doesn't silently cut the scan short.
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for sc.Scan() {
+		lineNo++
+		line := sc.Text()
+
+		for _, rule := range s.rules {
+			matched, ok := rule.Match(line)
+			if !ok {
+				continue
+			}
+
+			result := ConfigScanResult{
+				File:     file.Name,
+				Line:     lineNo,
+				RuleID:   rule.ID(),
+				Severity: rule.Severity(),
+				Preview:  redact(matched),
+			}
+
+			if key, ok := kvKey(line); ok {
+				result.SuggestedSecret = matchingSecretName(key, knownSecrets, groupPatterns)
+			}
+
+			results = append(results, result)
+		}
+	}
+
+	return results
+}
+
+func kvKey(line string) (string, bool) {
+	m := kvLineRe.FindStringSubmatch(line)
+	if m == nil {
+		return "", false
+	}
+	return m[1], true
+}
+
+// matchingSecretName returns the name of a known secret (or secret group)
+// that the config key should probably be referencing instead of a literal
+// value: either a secret whose normalized name matches the key exactly, or a
+// group, as computed by the repo's configured prefix patterns, the key
+// falls into.
+func matchingSecretName(key string, knownSecrets []*model.Secret, groupPatterns []string) string {
+	normalizedKey := secret.NormalizePrefix(key)
+
+	for _, s := range knownSecrets {
+		if secret.NormalizePrefix(s.Name) == normalizedKey {
+			return s.Name
+		}
+	}
+
+	for _, raw := range groupPatterns {
+		pp := secret.ParsePrefixPattern(raw)
+		if pp == nil {
+			continue
+		}
+		if ok, group := pp.MatchSecret(key); ok {
+			return group
+		}
+	}
+
+	return ""
+}
+
+// redact shortens a match down to a safe-to-log preview instead of leaking
+// the full secret into logs or UI.
+func redact(match string) string {
+	const keep = 4
+	if len(match) <= keep*2 {
+		return "****"
+	}
+	return match[:keep] + "..." + match[len(match)-keep:]
+}
@@ -0,0 +1,63 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.woodpecker-ci.org/woodpecker/v3/shared/constant"
+)
+
+func TestRulesFromDefsPropagatesInvalidRegex(t *testing.T) {
+	_, err := RulesFromDefs([]constant.SecretScanRuleDef{
+		{ID: "broken", Pattern: "(unterminated", Severity: "high"},
+	})
+
+	assert.Error(t, err)
+}
+
+func TestRulesFromDefsDoesNotPanicOnInvalidRegex(t *testing.T) {
+	assert.NotPanics(t, func() {
+		_, _ = RulesFromDefs([]constant.SecretScanRuleDef{
+			{ID: "broken", Pattern: "(unterminated", Severity: "high"},
+		})
+	})
+}
+
+func TestBuiltinRulesCompile(t *testing.T) {
+	rules := BuiltinRules()
+	assert.NotEmpty(t, rules)
+}
+
+func TestRulesFromDefsExtendsBuiltinRules(t *testing.T) {
+	defs := append(append([]constant.SecretScanRuleDef{}, constant.DefaultSecretScanRules...), constant.SecretScanRuleDef{
+		ID:       "custom-internal-token",
+		Pattern:  `INTERNAL_[A-Z0-9]{16}`,
+		Severity: "high",
+	})
+
+	rules, err := RulesFromDefs(defs)
+	assert.NoError(t, err)
+
+	found := false
+	for _, r := range rules {
+		if r.ID() == "custom-internal-token" {
+			found = true
+		}
+	}
+	assert.True(t, found, "admin-supplied rule should be present in the compiled ruleset")
+}
@@ -15,6 +15,7 @@
 package secret
 
 import (
+	"fmt"
 	"regexp"
 	"sort"
 	"strings"
@@ -32,6 +33,26 @@ type SecretGroup struct {
 type SecretGroups struct {
 	Groups   map[string][]*model.Secret `json:"groups"`
 	Patterns []string                   `json:"patterns"`
+	// Warnings lists secrets that look like they were meant to belong to a
+	// pattern-based group but didn't match the template regex, e.g.
+	// "PROD_STAGING_KEY" against pattern "PROD_{??}_KEY".
+	Warnings []Warning `json:"warnings,omitempty"`
+}
+
+// Warning flags a secret whose name matches a pattern's base prefix but not
+// its full template shape, so it silently ended up in "General" instead of
+// the group the user probably intended.
+type Warning struct {
+	Secret  string `json:"secret"`
+	Pattern string `json:"pattern"`
+	Reason  string `json:"reason"`
+}
+
+// ResolveOptions controls how SecretGroups.ResolveForEnv expands a group.
+type ResolveOptions struct {
+	// InjectAliases also exposes a group secret under its unqualified
+	// suffix, e.g. "PROD_AE_DB_URL" becomes available as "DB_URL" too.
+	InjectAliases bool
 }
 
 // PrefixPattern represents a parsed prefix pattern
@@ -132,6 +153,7 @@ func (pp *PrefixPattern) MatchSecret(secretName string) (bool, string) {
 func GroupSecrets(secrets []*model.Secret, patterns []string) *SecretGroups {
 	groups := make(map[string][]*model.Secret)
 	groups["General"] = make([]*model.Secret, 0)
+	var warnings []Warning
 
 	// Parse all patterns
 	parsedPatterns := make([]*PrefixPattern, 0, len(patterns))
@@ -142,7 +164,8 @@ func GroupSecrets(secrets []*model.Secret, patterns []string) *SecretGroups {
 		}
 	}
 
-	// Sort patterns by priority (highest first)
+	// Sort patterns by priority (highest first), so a secret that could
+	// match more than one pattern is always grouped by the most specific one
 	sort.Slice(parsedPatterns, func(i, j int) bool {
 		return parsedPatterns[i].Priority > parsedPatterns[j].Priority
 	})
@@ -167,6 +190,7 @@ func GroupSecrets(secrets []*model.Secret, patterns []string) *SecretGroups {
 		// If no pattern matched, add to General group
 		if !matched {
 			groups["General"] = append(groups["General"], secret)
+			warnings = append(warnings, orphanWarnings(secret, parsedPatterns)...)
 		}
 	}
 
@@ -178,7 +202,99 @@ func GroupSecrets(secrets []*model.Secret, patterns []string) *SecretGroups {
 	return &SecretGroups{
 		Groups:   groups,
 		Patterns: patterns,
+		Warnings: warnings,
+	}
+}
+
+// orphanWarnings flags the common footgun where a secret name matches a
+// template pattern's base prefix (e.g. "PROD_") but not its full template
+// shape (e.g. "PROD_{??}"), so it silently ends up in "General" instead of
+// the group the user probably intended, e.g. "PROD_STAGING_KEY" vs
+// "PROD_{??}_KEY".
+func orphanWarnings(secret *model.Secret, parsedPatterns []*PrefixPattern) []Warning {
+	var warnings []Warning
+
+	normalized := NormalizePrefix(secret.Name)
+	for _, pp := range parsedPatterns {
+		if !pp.IsTemplate {
+			continue
+		}
+		if strings.HasPrefix(normalized, pp.NormalizedBase+"_") {
+			// parsedPatterns is priority-sorted, so the first base match is
+			// the most specific one worth surfacing - one warning per
+			// orphaned secret, not one per pattern sharing that base
+			warnings = append(warnings, Warning{
+				Secret:  secret.Name,
+				Pattern: pp.Original,
+				Reason:  fmt.Sprintf("matches base prefix %q but not the %q template shape", pp.NormalizedBase, pp.Original),
+			})
+			break
+		}
+	}
+
+	return warnings
+}
+
+// ResolveForEnv returns the secrets available to a step declaring
+// "environment: <tag>": every secret in the "General" group plus those in
+// the specific group that tag resolves to via the configured prefix
+// patterns. With opts.InjectAliases, group secrets are also exposed under
+// their unqualified suffix, e.g. "PROD_AE_DB_URL" becomes available as
+// "DB_URL" too.
+//
+// Nothing else in the server calls it: no pipeline compiler step resolves an
+// "environment: <tag>" declaration through it.
+func (sg *SecretGroups) ResolveForEnv(tag string, opts ResolveOptions) []*model.Secret {
+	var resolved []*model.Secret
+	resolved = append(resolved, sg.Groups["General"]...)
+
+	seenNames := make(map[string]bool, len(resolved))
+	for _, s := range resolved {
+		seenNames[NormalizePrefix(s.Name)] = true
+	}
+
+	groupName := NormalizePrefix(tag)
+	groupSecrets, ok := sg.Groups[groupName]
+	if !ok {
+		return resolved
+	}
+
+	for _, s := range groupSecrets {
+		resolved = append(resolved, s)
+		seenNames[NormalizePrefix(s.Name)] = true
+
+		if !opts.InjectAliases {
+			continue
+		}
+		alias := unqualifiedAlias(s.Name, groupName)
+		// skip aliases that would collide with an existing secret name -
+		// e.g. a "General" secret already named "DB_URL" always wins over
+		// an alias derived from "PROD_AE_DB_URL"
+		if alias == "" || seenNames[NormalizePrefix(alias)] {
+			continue
+		}
+		aliased := *s
+		aliased.Name = alias
+		resolved = append(resolved, &aliased)
+		seenNames[NormalizePrefix(alias)] = true
+	}
+
+	return resolved
+}
+
+// unqualifiedAlias strips "<groupName>_" from secretName, e.g.
+// unqualifiedAlias("prod_ae_db_url", "PROD_AE") returns "db_url". The split
+// point is located on the normalized (upper-cased) name, but the returned
+// alias is sliced out of the original secretName so its casing is preserved
+// rather than forced to upper case.
+func unqualifiedAlias(secretName, groupName string) string {
+	normalized := NormalizePrefix(secretName)
+	prefix := groupName + "_"
+	if !strings.HasPrefix(normalized, prefix) {
+		return ""
 	}
+	trimmed := strings.TrimRight(secretName, "_-")
+	return trimmed[len(prefix):]
 }
 
 // GetSortedGroupNames returns all group names sorted alphabetically with "General" first
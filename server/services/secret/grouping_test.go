@@ -0,0 +1,85 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secret
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+)
+
+func secretNames(secrets []*model.Secret) []string {
+	names := make([]string, len(secrets))
+	for i, s := range secrets {
+		names[i] = s.Name
+	}
+	return names
+}
+
+func TestResolveForEnvReturnsGeneralPlusMatchingGroup(t *testing.T) {
+	sg := GroupSecrets([]*model.Secret{
+		{Name: "api_key"},
+		{Name: "prod_ae_db_url"},
+		{Name: "prod_ae_api_token"},
+	}, []string{"PROD_{??}"})
+
+	resolved := sg.ResolveForEnv("prod_ae", ResolveOptions{})
+
+	assert.ElementsMatch(t, []string{"api_key", "prod_ae_db_url", "prod_ae_api_token"}, secretNames(resolved))
+}
+
+func TestResolveForEnvInjectAliasesPreservesSecretCasing(t *testing.T) {
+	sg := GroupSecrets([]*model.Secret{
+		{Name: "prod_ae_db_url"},
+	}, []string{"PROD_{??}"})
+
+	resolved := sg.ResolveForEnv("prod_ae", ResolveOptions{InjectAliases: true})
+
+	assert.ElementsMatch(t, []string{"prod_ae_db_url", "db_url"}, secretNames(resolved))
+}
+
+func TestResolveForEnvInjectAliasesSkipsCollisionWithGeneralSecret(t *testing.T) {
+	sg := GroupSecrets([]*model.Secret{
+		{Name: "db_url"},
+		{Name: "prod_ae_db_url"},
+	}, []string{"PROD_{??}"})
+
+	resolved := sg.ResolveForEnv("prod_ae", ResolveOptions{InjectAliases: true})
+
+	// the General "db_url" wins; no second alias secret is injected
+	assert.ElementsMatch(t, []string{"db_url", "prod_ae_db_url"}, secretNames(resolved))
+}
+
+func TestResolveForEnvUnknownTagReturnsOnlyGeneral(t *testing.T) {
+	sg := GroupSecrets([]*model.Secret{
+		{Name: "api_key"},
+		{Name: "prod_ae_db_url"},
+	}, []string{"PROD_{??}"})
+
+	resolved := sg.ResolveForEnv("staging", ResolveOptions{})
+
+	assert.ElementsMatch(t, []string{"api_key"}, secretNames(resolved))
+}
+
+func TestOrphanWarningsOnePerSecretNotPerPattern(t *testing.T) {
+	sg := GroupSecrets([]*model.Secret{
+		{Name: "prod_staging_key"},
+	}, []string{"PROD_{??}", "PROD_{?}"})
+
+	assert.Len(t, sg.Warnings, 1)
+	assert.Equal(t, "prod_staging_key", sg.Warnings[0].Secret)
+}
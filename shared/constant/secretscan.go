@@ -0,0 +1,58 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package constant
+
+// SecretScanRuleDef describes a single secret-leak detection rule.
+// It is plain data so operators can load additional rule tables from admin
+// config without recompiling; server/services/config/scan turns these into
+// compiled rules.
+type SecretScanRuleDef struct {
+	// ID uniquely identifies the rule, e.g. "aws-access-key-id".
+	ID string
+	// Pattern is the regular expression used to detect the secret.
+	Pattern string
+	// Severity is one of "low", "medium", "high" or "critical".
+	Severity string
+}
+
+// DefaultSecretScanRules is the built-in ruleset. An admin-supplied rule
+// table is appended to it rather than replacing it.
+var DefaultSecretScanRules = []SecretScanRuleDef{
+	{
+		ID:       "aws-access-key-id",
+		Pattern:  `AKIA[0-9A-Z]{16}`,
+		Severity: "high",
+	},
+	{
+		ID:       "aws-secret-access-key",
+		Pattern:  `(?i)aws_secret_access_key\s*[:=]\s*['"]?[A-Za-z0-9/+=]{40}['"]?`,
+		Severity: "critical",
+	},
+	{
+		ID:       "github-pat",
+		Pattern:  `gh[pousr]_[0-9A-Za-z]{36,255}`,
+		Severity: "high",
+	},
+	{
+		ID:       "jwt",
+		Pattern:  `eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`,
+		Severity: "medium",
+	},
+	{
+		ID:       "pem-private-key-block",
+		Pattern:  `-----BEGIN [A-Z ]*PRIVATE KEY-----`,
+		Severity: "critical",
+	},
+}